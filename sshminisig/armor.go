@@ -0,0 +1,62 @@
+package sshminisig
+
+import (
+	"bytes"
+	"encoding/base64"
+	"errors"
+
+	"golang.org/x/crypto/ssh"
+)
+
+// armorLineLength is the line length ssh-keygen uses when armoring signatures.
+const armorLineLength = 70
+
+// Armor converts an sshminisig back to an armored SSH signature,
+// using the public key and namespace that the minisig leaves out.
+// Armor undoes Encode for every algorithm Encode accepts, legacy ssh-rsa
+// included: for a signature made by ssh-keygen -Y sign, the result is the
+// original armored signature, byte for byte.
+//
+// Armor does not check the signature or its algorithm; use Verify for that.
+func Armor(minisig string, pub ssh.PublicKey, namespace string) ([]byte, error) {
+	if pub == nil {
+		return nil, errors.New("nil public key")
+	}
+	if namespace == "" {
+		return nil, errors.New("empty namespace")
+	}
+	algs, sigBytes, err := Decode(minisig)
+	if err != nil {
+		return nil, err
+	}
+	sig, err := sshSignature(algs.Sig, sigBytes)
+	if err != nil {
+		return nil, err
+	}
+
+	var sigBlob []byte
+	sigBlob = appendString(sigBlob, []byte(sig.Format))
+	sigBlob = appendString(sigBlob, sig.Blob)
+	sigBlob = append(sigBlob, sig.Rest...)
+
+	blob := []byte("SSHSIG")
+	blob = append(blob, 0, 0, 0, 1) // version
+	blob = appendString(blob, pub.Marshal())
+	blob = appendString(blob, []byte(namespace))
+	blob = appendString(blob, nil) // reserved
+	blob = appendString(blob, []byte(algs.Hash))
+	blob = appendString(blob, sigBlob)
+
+	// encoding/pem wraps at 64 columns; match ssh-keygen instead.
+	encoded := base64.StdEncoding.EncodeToString(blob)
+	var buf bytes.Buffer
+	buf.WriteString("-----BEGIN SSH SIGNATURE-----\n")
+	for len(encoded) > armorLineLength {
+		buf.WriteString(encoded[:armorLineLength])
+		buf.WriteByte('\n')
+		encoded = encoded[armorLineLength:]
+	}
+	buf.WriteString(encoded)
+	buf.WriteString("\n-----END SSH SIGNATURE-----\n")
+	return buf.Bytes(), nil
+}
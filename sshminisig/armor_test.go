@@ -0,0 +1,109 @@
+package sshminisig
+
+import (
+	"bytes"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"golang.org/x/crypto/ssh"
+)
+
+func TestArmorRoundTrip(t *testing.T) {
+	for _, kt := range verifyKeyTypes {
+		t.Run(kt.name, func(t *testing.T) {
+			keyPath := generateKey(t, kt.keygenArgs)
+			armored := signMessage(t, keyPath, "file", "message")
+			minisig, err := Encode(armored)
+			if err != nil {
+				t.Fatalf("Encode failed: %v", err)
+			}
+
+			got, err := Armor(minisig, readPublicKey(t, keyPath), "file")
+			if err != nil {
+				t.Fatalf("Armor failed: %v", err)
+			}
+			if !bytes.Equal(got, armored) {
+				t.Errorf("got\n%s\nwant\n%s", got, armored)
+			}
+		})
+	}
+}
+
+// TestArmorSSHKeygen checks that ssh-keygen -Y verify accepts the armored
+// form of a signature made by Sign.
+func TestArmorSSHKeygen(t *testing.T) {
+	for _, kt := range verifyKeyTypes {
+		t.Run(kt.name, func(t *testing.T) {
+			signer := readSigner(t, generateKey(t, kt.keygenArgs))
+			minisig, err := Sign(signer, "file", strings.NewReader("message"))
+			if err != nil {
+				t.Fatalf("Sign failed: %v", err)
+			}
+			armored, err := Armor(minisig, signer.PublicKey(), "file")
+			if err != nil {
+				t.Fatalf("Armor failed: %v", err)
+			}
+
+			dir := t.TempDir()
+			sigPath := filepath.Join(dir, "message.sig")
+			signersPath := filepath.Join(dir, "allowed_signers")
+			if err := os.WriteFile(sigPath, armored, 0o600); err != nil {
+				t.Fatal(err)
+			}
+			signers := "signer@example.com " + string(ssh.MarshalAuthorizedKey(signer.PublicKey()))
+			if err := os.WriteFile(signersPath, []byte(signers), 0o600); err != nil {
+				t.Fatal(err)
+			}
+
+			cmd := exec.Command("ssh-keygen", "-Y", "verify", "-f", signersPath, "-I", "signer@example.com", "-n", "file", "-s", sigPath)
+			cmd.Stdin = strings.NewReader("message")
+			if out, err := cmd.CombinedOutput(); err != nil {
+				t.Fatalf("ssh-keygen -Y verify failed: %v\n%s", err, out)
+			}
+		})
+	}
+}
+
+// TestArmorLegacyRSA checks that Armor round-trips legacy ssh-rsa minisigs,
+// which Encode accepts, and leaves rejecting them to Verify.
+func TestArmorLegacyRSA(t *testing.T) {
+	signer := readSigner(t, generateKey(t, []string{"-t", "rsa", "-b", "2048"}))
+	minisig := legacyRSAMinisig(t, signer, "file", "message")
+
+	armored, err := Armor(minisig, signer.PublicKey(), "file")
+	if err != nil {
+		t.Fatalf("Armor failed: %v", err)
+	}
+	got, err := Encode(armored)
+	if err != nil {
+		t.Fatalf("Encode failed: %v", err)
+	}
+	if got != minisig {
+		t.Errorf("got %q, want %q", got, minisig)
+	}
+}
+
+func TestArmorErrors(t *testing.T) {
+	keyPath := generateKey(t, []string{"-t", "ed25519"})
+	pub := readPublicKey(t, keyPath)
+	minisig, err := Encode(signMessage(t, keyPath, "file", "message"))
+	if err != nil {
+		t.Fatalf("Encode failed: %v", err)
+	}
+
+	if _, err := Armor(minisig, nil, "file"); err == nil {
+		t.Error("nil key: expected error")
+	}
+	if _, err := Armor(minisig, pub, ""); err == nil {
+		t.Error("empty namespace: expected error")
+	}
+	if _, err := Armor("x"+minisig[1:], pub, "file"); err == nil {
+		t.Error("invalid minisig: expected error")
+	}
+	if _, err := Armor(string(PrefixSKEd25519)+"AAAA", pub, "file"); err == nil {
+		t.Error("truncated security key signature: expected error")
+	}
+}
@@ -3,36 +3,193 @@
 // Usage:
 //
 //	ssh-keygen -Y sign -f ~/.ssh/id_ed25519 -n file < message.txt | sshminisig
+//	sshminisig [-json] [-o output] path...
 //
-// The output is written to stdout.
+// With no paths, it converts the signature on stdin.
+// Otherwise it converts each path in order: a file is read directly,
+// a directory contributes its *.sig files, and a glob pattern
+// (quoted, so the shell leaves it alone) contributes its matches.
+// The output is one minisig per line, or with -json an array of
+// {"file", "minisig"} objects.
+//
+// The output is written to stdout, or to the file named by -o.
+// Flags must come before paths.
 package main
 
 import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"flag"
 	"fmt"
 	"io"
 	"os"
+	"path/filepath"
+	"strings"
 
 	"github.com/boldsoftware/exe.dev/sshminisig"
 )
 
+// maxInputSize matches the largest armored signature sshminisig.Encode accepts.
+// Reading stops just past it, so a stray large file is never slurped whole.
+const maxInputSize = 8 * 1024
+
+// errUsage reports a command-line error that the flag set has already printed.
+var errUsage = errors.New("usage error")
+
 func main() {
-	if err := run(); err != nil {
+	err := run(os.Args[1:], os.Stdin, os.Stdout)
+	switch {
+	case err == nil, errors.Is(err, flag.ErrHelp):
+	case errors.Is(err, errUsage):
+		os.Exit(2)
+	default:
 		fmt.Fprintf(os.Stderr, "sshminisig: %v\n", err)
 		os.Exit(1)
 	}
 }
 
-func run() error {
-	input, err := io.ReadAll(os.Stdin)
+// result is one converted signature, as emitted by -json.
+type result struct {
+	File    string `json:"file"`
+	Minisig string `json:"minisig"`
+}
+
+func run(args []string, stdin io.Reader, stdout io.Writer) error {
+	fs := flag.NewFlagSet("sshminisig", flag.ContinueOnError)
+	jsonOut := fs.Bool("json", false, "write a JSON array of {file, minisig} objects")
+	outPath := fs.String("o", "", "write output to `file` instead of stdout")
+	fs.Usage = func() {
+		fmt.Fprintf(fs.Output(), "usage: sshminisig [-json] [-o file] [path ...]\n")
+		fmt.Fprintf(fs.Output(), "Flags must come before paths.\n")
+		fs.PrintDefaults()
+	}
+	if err := fs.Parse(args); err != nil {
+		if errors.Is(err, flag.ErrHelp) {
+			return err
+		}
+		return errUsage
+	}
+
+	var results []result
+	if fs.NArg() == 0 {
+		input, err := readLimited(stdin)
+		if err != nil {
+			return fmt.Errorf("reading stdin: %w", err)
+		}
+		minisig, err := sshminisig.Encode(input)
+		if err != nil {
+			return err
+		}
+		results = []result{{File: "-", Minisig: minisig}}
+	} else {
+		paths, err := expandPaths(fs.Args())
+		if err != nil {
+			return err
+		}
+		for _, path := range paths {
+			minisig, err := encodeFile(path)
+			if err != nil {
+				return fmt.Errorf("%s: %w", path, err)
+			}
+			results = append(results, result{File: path, Minisig: minisig})
+		}
+	}
+
+	var out bytes.Buffer
+	switch {
+	case *jsonOut:
+		if err := writeJSON(&out, results); err != nil {
+			return err
+		}
+	case fs.NArg() == 0:
+		// A single stdin conversion prints the bare minisig, without a newline.
+		out.WriteString(results[0].Minisig)
+	default:
+		for _, r := range results {
+			fmt.Fprintln(&out, r.Minisig)
+		}
+	}
+	if *outPath == "" {
+		_, err := stdout.Write(out.Bytes())
+		return err
+	}
+	return os.WriteFile(*outPath, out.Bytes(), 0o644)
+}
+
+// encodeFile converts the armored signature in the file at path.
+func encodeFile(path string) (string, error) {
+	f, err := os.Open(path)
 	if err != nil {
-		return fmt.Errorf("reading stdin: %w", err)
+		return "", err
 	}
+	defer f.Close()
+	input, err := readLimited(f)
+	if err != nil {
+		return "", err
+	}
+	return sshminisig.Encode(input)
+}
 
-	minisig, err := sshminisig.Encode(input)
+// readLimited reads r to EOF, failing if it holds more than maxInputSize bytes.
+func readLimited(r io.Reader) ([]byte, error) {
+	b, err := io.ReadAll(io.LimitReader(r, maxInputSize+1))
 	if err != nil {
-		return err
+		return nil, err
+	}
+	if len(b) > maxInputSize {
+		return nil, errors.New("input too large for an armored signature")
+	}
+	return b, nil
+}
+
+// expandPaths resolves the command-line arguments to the signature files to convert.
+func expandPaths(args []string) ([]string, error) {
+	var paths []string
+	for _, arg := range args {
+		info, err := os.Stat(arg)
+		switch {
+		case err == nil && info.IsDir():
+			// os.ReadDir returns entries sorted by name.
+			entries, err := os.ReadDir(arg)
+			if err != nil {
+				return nil, err
+			}
+			n := len(paths)
+			for _, e := range entries {
+				if !e.IsDir() && filepath.Ext(e.Name()) == ".sig" {
+					paths = append(paths, filepath.Join(arg, e.Name()))
+				}
+			}
+			if len(paths) == n {
+				return nil, fmt.Errorf("%s: no .sig files in directory", arg)
+			}
+		case err == nil:
+			paths = append(paths, arg)
+		case errors.Is(err, os.ErrNotExist) && strings.ContainsAny(arg, `*?[`):
+			matches, err := filepath.Glob(arg)
+			if err != nil {
+				return nil, fmt.Errorf("%s: %w", arg, err)
+			}
+			// Unlike directory arguments, directories matched by a glob are skipped.
+			n := len(paths)
+			for _, match := range matches {
+				if info, err := os.Stat(match); err == nil && !info.IsDir() {
+					paths = append(paths, match)
+				}
+			}
+			if len(paths) == n {
+				return nil, fmt.Errorf("%s: no matching files", arg)
+			}
+		default:
+			return nil, err
+		}
 	}
+	return paths, nil
+}
 
-	fmt.Print(minisig)
-	return nil
+func writeJSON(w io.Writer, results []result) error {
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	return enc.Encode(results)
 }
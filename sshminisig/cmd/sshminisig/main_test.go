@@ -0,0 +1,191 @@
+package main
+
+import (
+	"bytes"
+	"crypto/ed25519"
+	"crypto/rand"
+	"encoding/json"
+	"errors"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/boldsoftware/exe.dev/sshminisig"
+	"golang.org/x/crypto/ssh"
+)
+
+func TestExpandPaths(t *testing.T) {
+	dir := t.TempDir()
+	for _, name := range []string{"b.sig", "a.sig", "notes.txt", "c.sig/x.sig"} {
+		writeFile(t, filepath.Join(dir, name), "")
+	}
+	empty := filepath.Join(dir, "empty")
+	if err := os.Mkdir(empty, 0o755); err != nil {
+		t.Fatal(err)
+	}
+	join := func(names ...string) []string {
+		var paths []string
+		for _, name := range names {
+			paths = append(paths, filepath.Join(dir, name))
+		}
+		return paths
+	}
+
+	tests := []struct {
+		name    string
+		args    []string
+		want    []string
+		wantErr bool
+	}{
+		{"file", join("notes.txt"), join("notes.txt"), false},
+		{"directory picks sig files in name order", []string{dir}, join("a.sig", "b.sig"), false},
+		{"glob", join("*.txt"), join("notes.txt"), false},
+		{"glob skips directories", join("*.sig"), join("a.sig", "b.sig"), false},
+		{"order preserved", join("notes.txt", "b.sig"), join("notes.txt", "b.sig"), false},
+		{"missing file", join("missing.sig"), nil, true},
+		{"glob without matches", join("*.pem"), nil, true},
+		{"glob matching only directories", join("c.*"), nil, true},
+		{"directory without sig files", []string{empty}, nil, true},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			got, err := expandPaths(tc.args)
+			if tc.wantErr {
+				if err == nil {
+					t.Errorf("got %v, want error", got)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !slices.Equal(got, tc.want) {
+				t.Errorf("got %v, want %v", got, tc.want)
+			}
+		})
+	}
+}
+
+func TestRun(t *testing.T) {
+	dir := t.TempDir()
+	minisigA, armoredA := signature(t, "a")
+	minisigB, armoredB := signature(t, "b")
+	pathA := filepath.Join(dir, "a.sig")
+	pathB := filepath.Join(dir, "b.sig")
+	writeFile(t, pathA, armoredA)
+	writeFile(t, pathB, armoredB)
+
+	t.Run("stdin", func(t *testing.T) {
+		var out bytes.Buffer
+		if err := run(nil, strings.NewReader(armoredA), &out); err != nil {
+			t.Fatal(err)
+		}
+		if out.String() != minisigA {
+			t.Errorf("got %q, want %q", out.String(), minisigA)
+		}
+	})
+
+	t.Run("lines", func(t *testing.T) {
+		var out bytes.Buffer
+		if err := run([]string{dir}, nil, &out); err != nil {
+			t.Fatal(err)
+		}
+		if want := minisigA + "\n" + minisigB + "\n"; out.String() != want {
+			t.Errorf("got %q, want %q", out.String(), want)
+		}
+	})
+
+	t.Run("json", func(t *testing.T) {
+		var out bytes.Buffer
+		if err := run([]string{"-json", pathB, pathA}, nil, &out); err != nil {
+			t.Fatal(err)
+		}
+		var got []result
+		if err := json.Unmarshal(out.Bytes(), &got); err != nil {
+			t.Fatalf("parsing %q: %v", out.String(), err)
+		}
+		want := []result{{pathB, minisigB}, {pathA, minisigA}}
+		if !slices.Equal(got, want) {
+			t.Errorf("got %v, want %v", got, want)
+		}
+	})
+
+	t.Run("output file", func(t *testing.T) {
+		outPath := filepath.Join(t.TempDir(), "out.txt")
+		var out bytes.Buffer
+		if err := run([]string{"-o", outPath, pathA}, nil, &out); err != nil {
+			t.Fatal(err)
+		}
+		if out.Len() != 0 {
+			t.Errorf("wrote %q to stdout, want nothing", out.String())
+		}
+		got, err := os.ReadFile(outPath)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if string(got) != minisigA+"\n" {
+			t.Errorf("got %q, want %q", got, minisigA+"\n")
+		}
+	})
+
+	t.Run("bad flag", func(t *testing.T) {
+		// The flag set prints the error itself; run reports only errUsage.
+		err := run([]string{"-x", pathA}, nil, &bytes.Buffer{})
+		if !errors.Is(err, errUsage) {
+			t.Errorf("got %v, want errUsage", err)
+		}
+	})
+
+	t.Run("too large", func(t *testing.T) {
+		big := filepath.Join(t.TempDir(), "big.sig")
+		writeFile(t, big, strings.Repeat("x", maxInputSize+1))
+		err := run([]string{big}, nil, &bytes.Buffer{})
+		if err == nil || !strings.Contains(err.Error(), "too large") {
+			t.Errorf("got %v, want too large error", err)
+		}
+	})
+
+	t.Run("invalid signature names file", func(t *testing.T) {
+		bad := filepath.Join(t.TempDir(), "bad.sig")
+		writeFile(t, bad, "not a signature")
+		err := run([]string{bad}, nil, &bytes.Buffer{})
+		if err == nil || !strings.Contains(err.Error(), bad) {
+			t.Errorf("got %v, want error naming %s", err, bad)
+		}
+	})
+}
+
+// signature signs message with a fresh key and returns it as both
+// a minisig and an armored signature.
+func signature(t *testing.T, message string) (minisig, armored string) {
+	t.Helper()
+	_, priv, err := ed25519.GenerateKey(rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	signer, err := ssh.NewSignerFromKey(priv)
+	if err != nil {
+		t.Fatal(err)
+	}
+	minisig, err = sshminisig.Sign(signer, "file", strings.NewReader(message))
+	if err != nil {
+		t.Fatal(err)
+	}
+	b, err := sshminisig.Armor(minisig, signer.PublicKey(), "file")
+	if err != nil {
+		t.Fatal(err)
+	}
+	return minisig, string(b)
+}
+
+func writeFile(t *testing.T, path, content string) {
+	t.Helper()
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+		t.Fatal(err)
+	}
+}
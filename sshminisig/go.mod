@@ -1,3 +1,5 @@
 module github.com/boldsoftware/exe.dev/sshminisig
 
 go 1.26.4
+
+require golang.org/x/crypto v0.57.0
@@ -0,0 +1,307 @@
+package sshminisig
+
+import (
+	"bytes"
+	"crypto/sha1"
+	"crypto/sha256"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"math/big"
+
+	"golang.org/x/crypto/ssh"
+)
+
+// ErrRevoked is returned when a signature was made by a revoked key or certificate.
+var ErrRevoked = errors.New("key is revoked")
+
+// KRL section types, from OpenSSH's PROTOCOL.krl.
+const (
+	krlMagic              = "SSHKRL\n\x00"
+	krlFormatVersion      = 1
+	krlSectionCerts       = 1
+	krlSectionExplicitKey = 2
+	krlSectionSHA1        = 3
+	krlSectionSignature   = 4
+	krlSectionSHA256      = 5
+	krlSectionExtension   = 255
+	krlCertSerialList     = 0x20
+	krlCertSerialRange    = 0x21
+	krlCertSerialBitmap   = 0x22
+	krlCertKeyID          = 0x23
+	krlCertExtension      = 0x39
+)
+
+// KRL is a parsed OpenSSH Key Revocation List, as produced by ssh-keygen -k.
+//
+// Signed KRLs are not supported.
+type KRL struct {
+	Version uint64 // krl_version; ssh-keygen -z sets it
+	Comment string
+
+	keys   map[string]bool // plain public key blobs
+	sha1   map[string]bool // SHA-1 of plain public key blobs
+	sha256 map[string]bool // SHA-256 of plain public key blobs
+	certs  []*krlCerts
+}
+
+// krlCerts holds the certificate revocations for one CA.
+type krlCerts struct {
+	caKey   []byte // plain public key blob of the CA; nil means any CA
+	serials []serialRange
+	keyIDs  map[string]bool
+}
+
+// serialRange is an inclusive range of certificate serial numbers.
+type serialRange struct {
+	min, max uint64
+}
+
+// ParseKRL parses a binary OpenSSH Key Revocation List.
+func ParseKRL(data []byte) (*KRL, error) {
+	b := data
+	if len(b) < len(krlMagic) || string(b[:len(krlMagic)]) != krlMagic {
+		return nil, errors.New("invalid KRL magic")
+	}
+	b = b[len(krlMagic):]
+
+	var formatVersion uint32
+	if formatVersion, b = readUint32(b); b == nil || formatVersion != krlFormatVersion {
+		return nil, errors.New("unsupported KRL format version")
+	}
+
+	krl := &KRL{
+		keys:   make(map[string]bool),
+		sha1:   make(map[string]bool),
+		sha256: make(map[string]bool),
+	}
+	krl.Version, b = readUint64(b)
+	_, b = readUint64(b) // generated_date
+	_, b = readUint64(b) // flags
+	_, b = readString(b) // reserved
+	var comment []byte
+	comment, b = readString(b)
+	if b == nil {
+		return nil, errors.New("truncated KRL header")
+	}
+	krl.Comment = string(comment)
+
+	for len(b) > 0 {
+		sectionType := b[0]
+		var section []byte
+		section, b = readString(b[1:])
+		if b == nil {
+			return nil, fmt.Errorf("truncated KRL section %d", sectionType)
+		}
+
+		var err error
+		switch sectionType {
+		case krlSectionCerts:
+			err = krl.parseCerts(section)
+		case krlSectionExplicitKey:
+			err = addStrings(krl.keys, section)
+		case krlSectionSHA1:
+			err = addStrings(krl.sha1, section)
+		case krlSectionSHA256:
+			err = addStrings(krl.sha256, section)
+		case krlSectionSignature:
+			err = errors.New("signed KRLs are not supported")
+		case krlSectionExtension:
+			err = checkExtension(section)
+		default:
+			err = fmt.Errorf("unknown KRL section type %d", sectionType)
+		}
+		if err != nil {
+			return nil, err
+		}
+	}
+	return krl, nil
+}
+
+// parseCerts parses a KRL_SECTION_CERTIFICATES section.
+func (krl *KRL) parseCerts(b []byte) error {
+	caKey, b := readString(b)
+	_, b = readString(b) // reserved
+	if b == nil {
+		return errors.New("truncated KRL certificate section")
+	}
+
+	certs := &krlCerts{keyIDs: make(map[string]bool)}
+	if len(caKey) > 0 {
+		ca, err := ssh.ParsePublicKey(caKey)
+		if err != nil {
+			return fmt.Errorf("invalid KRL CA key: %w", err)
+		}
+		certs.caKey = plainKeyBlob(ca)
+	}
+
+	for len(b) > 0 {
+		sectionType := b[0]
+		var section []byte
+		section, b = readString(b[1:])
+		if b == nil {
+			return fmt.Errorf("truncated KRL certificate section %#x", sectionType)
+		}
+
+		switch sectionType {
+		case krlCertSerialList:
+			for len(section) > 0 {
+				var serial uint64
+				if serial, section = readUint64(section); section == nil {
+					return errors.New("truncated KRL serial list")
+				}
+				certs.serials = append(certs.serials, serialRange{serial, serial})
+			}
+		case krlCertSerialRange:
+			var lo, hi uint64
+			lo, section = readUint64(section)
+			hi, section = readUint64(section)
+			if section == nil || len(section) != 0 || lo > hi {
+				return errors.New("invalid KRL serial range")
+			}
+			certs.serials = append(certs.serials, serialRange{lo, hi})
+		case krlCertSerialBitmap:
+			var offset uint64
+			var bitmap []byte
+			offset, section = readUint64(section)
+			bitmap, section = readString(section)
+			if section == nil || len(section) != 0 {
+				return errors.New("invalid KRL serial bitmap")
+			}
+			certs.serials = append(certs.serials, bitmapRanges(offset, new(big.Int).SetBytes(bitmap))...)
+		case krlCertKeyID:
+			if err := addStrings(certs.keyIDs, section); err != nil {
+				return err
+			}
+		case krlCertExtension:
+			if err := checkExtension(section); err != nil {
+				return err
+			}
+		default:
+			return fmt.Errorf("unknown KRL certificate section type %#x", sectionType)
+		}
+	}
+
+	krl.certs = append(krl.certs, certs)
+	return nil
+}
+
+// bitmapRanges converts a KRL serial bitmap, where bit i set means serial
+// offset+i is revoked, to serial ranges.
+func bitmapRanges(offset uint64, bitmap *big.Int) []serialRange {
+	var ranges []serialRange
+	for i := 0; i < bitmap.BitLen(); i++ {
+		if bitmap.Bit(i) == 0 {
+			continue
+		}
+		serial := offset + uint64(i)
+		if n := len(ranges); n > 0 && ranges[n-1].max+1 == serial {
+			ranges[n-1].max = serial
+			continue
+		}
+		ranges = append(ranges, serialRange{serial, serial})
+	}
+	return ranges
+}
+
+// addStrings adds each SSH-style string in b to set.
+func addStrings(set map[string]bool, b []byte) error {
+	for len(b) > 0 {
+		var s []byte
+		if s, b = readString(b); b == nil {
+			return errors.New("truncated KRL section")
+		}
+		set[string(s)] = true
+	}
+	return nil
+}
+
+// checkExtension rejects critical KRL extensions, none of which are understood.
+// Non-critical extensions are ignored.
+func checkExtension(b []byte) error {
+	name, b := readString(b)
+	if len(b) < 1 {
+		return errors.New("truncated KRL extension")
+	}
+	if b[0] != 0 {
+		return fmt.Errorf("unsupported critical KRL extension %q", name)
+	}
+	return nil
+}
+
+// IsRevoked reports whether krl revokes pub. A certificate is revoked if the
+// certificate itself, its underlying key, or its signing CA key is revoked.
+// IsRevoked does not validate certificates; see Verifier.
+func (krl *KRL) IsRevoked(pub ssh.PublicKey) bool {
+	if krl.isKeyRevoked(pub) {
+		return true
+	}
+	cert, ok := pub.(*ssh.Certificate)
+	if !ok {
+		return false
+	}
+	if krl.isKeyRevoked(cert.SignatureKey) {
+		return true
+	}
+	caKey := plainKeyBlob(cert.SignatureKey)
+	for _, certs := range krl.certs {
+		if certs.caKey != nil && !bytes.Equal(certs.caKey, caKey) {
+			continue
+		}
+		if certs.isCertRevoked(cert) {
+			return true
+		}
+	}
+	return false
+}
+
+// isKeyRevoked reports whether the plain key underlying pub is revoked
+// explicitly or by fingerprint.
+func (krl *KRL) isKeyRevoked(pub ssh.PublicKey) bool {
+	blob := plainKeyBlob(pub)
+	sha1Sum := sha1.Sum(blob)
+	sha256Sum := sha256.Sum256(blob)
+	return krl.keys[string(blob)] || krl.sha1[string(sha1Sum[:])] || krl.sha256[string(sha256Sum[:])]
+}
+
+// isCertRevoked reports whether cert is revoked by key ID or serial.
+func (certs *krlCerts) isCertRevoked(cert *ssh.Certificate) bool {
+	if certs.keyIDs[cert.KeyId] {
+		return true
+	}
+	// As in OpenSSH, a zero serial means "no serial" and is never revoked by serial.
+	if cert.Serial == 0 {
+		return false
+	}
+	for _, r := range certs.serials {
+		if r.min <= cert.Serial && cert.Serial <= r.max {
+			return true
+		}
+	}
+	return false
+}
+
+// plainKeyBlob returns the wire encoding of pub, or of the key underlying
+// pub if it is a certificate.
+func plainKeyBlob(pub ssh.PublicKey) []byte {
+	if cert, ok := pub.(*ssh.Certificate); ok {
+		pub = cert.Key
+	}
+	return pub.Marshal()
+}
+
+// readUint32 reads a big-endian uint32.
+func readUint32(b []byte) (uint32, []byte) {
+	if len(b) < 4 {
+		return 0, nil
+	}
+	return binary.BigEndian.Uint32(b), b[4:]
+}
+
+// readUint64 reads a big-endian uint64.
+func readUint64(b []byte) (uint64, []byte) {
+	if len(b) < 8 {
+		return 0, nil
+	}
+	return binary.BigEndian.Uint64(b), b[8:]
+}
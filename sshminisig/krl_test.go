@@ -0,0 +1,255 @@
+package sshminisig
+
+import (
+	"crypto/sha512"
+	"encoding/binary"
+	"errors"
+	"math/big"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"slices"
+	"strconv"
+	"strings"
+	"testing"
+
+	"golang.org/x/crypto/ssh"
+)
+
+func TestKRLKeys(t *testing.T) {
+	explicit := generateKey(t, []string{"-t", "ed25519"})
+	bySHA1 := generateKey(t, []string{"-t", "ecdsa", "-b", "256"})
+	bySHA256 := generateKey(t, []string{"-t", "ed25519"})
+	other := generateKey(t, []string{"-t", "ed25519"})
+
+	spec := "key: " + readFile(t, explicit+".pub") +
+		"sha1: " + readFile(t, bySHA1+".pub") +
+		"hash: " + fingerprint(t, bySHA256) + "\n"
+	krl := generateKRL(t, spec, "-z", "7")
+
+	if krl.Version != 7 {
+		t.Errorf("Version: got %d, want 7", krl.Version)
+	}
+	for _, keyPath := range []string{explicit, bySHA1, bySHA256} {
+		if !krl.IsRevoked(readPublicKey(t, keyPath)) {
+			t.Errorf("%s: not revoked", filepath.Base(keyPath))
+		}
+	}
+	if krl.IsRevoked(readPublicKey(t, other)) {
+		t.Error("unlisted key revoked")
+	}
+}
+
+func TestKRLCertificates(t *testing.T) {
+	ca := generateKey(t, []string{"-t", "ed25519"})
+	otherCA := generateKey(t, []string{"-t", "ed25519"})
+	user := generateKey(t, []string{"-t", "ed25519"})
+
+	spec := "serial: 5\nserial: 10-20\nid: revoked-id\n"
+	krl := generateKRL(t, spec, "-s", ca+".pub")
+
+	tests := []struct {
+		name    string
+		ca      string
+		keyID   string
+		serial  uint64
+		revoked bool
+	}{
+		{"listed serial", ca, "ok", 5, true},
+		{"serial in range", ca, "ok", 15, true},
+		{"serial outside range", ca, "ok", 21, false},
+		{"revoked key id", ca, "revoked-id", 100, true},
+		{"zero serial", ca, "ok", 0, false},
+		{"other CA", otherCA, "revoked-id", 5, false},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			cert := signCert(t, tc.ca, user, tc.keyID, tc.serial)
+			if got := krl.IsRevoked(cert); got != tc.revoked {
+				t.Errorf("IsRevoked: got %v, want %v", got, tc.revoked)
+			}
+		})
+	}
+}
+
+func TestKRLAnyCA(t *testing.T) {
+	ca := generateKey(t, []string{"-t", "ed25519"})
+	user := generateKey(t, []string{"-t", "ed25519"})
+
+	krl := generateKRL(t, "id: revoked-id\n", "-s", "none")
+
+	if !krl.IsRevoked(signCert(t, ca, user, "revoked-id", 1)) {
+		t.Error("certificate with revoked key ID not revoked")
+	}
+	if krl.IsRevoked(signCert(t, ca, user, "ok", 1)) {
+		t.Error("certificate with other key ID revoked")
+	}
+}
+
+func TestKRLRevokedCA(t *testing.T) {
+	ca := generateKey(t, []string{"-t", "ed25519"})
+	user := generateKey(t, []string{"-t", "ed25519"})
+
+	krl := generateKRL(t, "key: "+readFile(t, ca+".pub"))
+
+	if !krl.IsRevoked(signCert(t, ca, user, "ok", 1)) {
+		t.Error("certificate signed by revoked CA not revoked")
+	}
+	if krl.IsRevoked(readPublicKey(t, user)) {
+		t.Error("plain user key revoked")
+	}
+}
+
+func TestBitmapRanges(t *testing.T) {
+	bitmap := new(big.Int)
+	for _, bit := range []int{0, 1, 2, 5, 8, 9} {
+		bitmap.SetBit(bitmap, bit, 1)
+	}
+	got := bitmapRanges(100, bitmap)
+	want := []serialRange{{100, 102}, {105, 105}, {108, 109}}
+	if !slices.Equal(got, want) {
+		t.Errorf("got %v, want %v", got, want)
+	}
+}
+
+func TestParseKRLErrors(t *testing.T) {
+	header := func(sections ...byte) []byte {
+		b := []byte(krlMagic)
+		b = binary.BigEndian.AppendUint32(b, krlFormatVersion)
+		b = append(b, make([]byte, 3*8)...) // version, date, flags
+		b = appendString(b, nil)            // reserved
+		b = appendString(b, nil)            // comment
+		return append(b, sections...)
+	}
+	section := func(sectionType byte, data []byte) []byte {
+		return appendString([]byte{sectionType}, data)
+	}
+	truncated := func(b []byte) []byte {
+		return b[:len(b)-2]
+	}
+	extension := func(critical byte) []byte {
+		return append(appendString(nil, []byte("ext@example.com")), critical)
+	}
+
+	if _, err := ParseKRL(header()); err != nil {
+		t.Fatalf("empty KRL: %v", err)
+	}
+	if _, err := ParseKRL(header(section(krlSectionExtension, extension(0))...)); err != nil {
+		t.Errorf("non-critical extension: %v", err)
+	}
+
+	tests := []struct {
+		name string
+		data []byte
+	}{
+		{"bad magic", []byte("SSHKRL\n\x01")},
+		{"truncated header", header()[:20]},
+		{"truncated section", truncated(header(section(krlSectionExplicitKey, appendString(nil, []byte("key")))...))},
+		{"truncated key list", header(section(krlSectionExplicitKey, []byte{0, 0, 0, 9})...)},
+		{"signature", header(section(krlSectionSignature, nil)...)},
+		{"critical extension", header(section(krlSectionExtension, extension(1))...)},
+		{"unknown section", header(section(42, nil)...)},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			if _, err := ParseKRL(tc.data); err == nil {
+				t.Error("expected error")
+			}
+		})
+	}
+}
+
+func TestVerifierKRL(t *testing.T) {
+	revoked := generateKey(t, []string{"-t", "ed25519"})
+	good := generateKey(t, []string{"-t", "ed25519"})
+	v := &Verifier{KRL: generateKRL(t, "key: "+readFile(t, revoked+".pub"))}
+	digest := sha512.Sum512([]byte("message"))
+
+	for _, keyPath := range []string{revoked, good} {
+		minisig, err := Encode(signMessage(t, keyPath, "file", "message"))
+		if err != nil {
+			t.Fatalf("Encode failed: %v", err)
+		}
+		pub := readPublicKey(t, keyPath)
+		errs := []error{
+			v.VerifyDigest(minisig, pub, "file", digest[:], HashSHA512),
+			v.Verify(pub, "file", strings.NewReader("message"), minisig),
+		}
+		for _, err := range errs {
+			if keyPath == revoked && !errors.Is(err, ErrRevoked) {
+				t.Errorf("revoked key: got %v, want ErrRevoked", err)
+			}
+			if keyPath == good && err != nil {
+				t.Errorf("good key: %v", err)
+			}
+		}
+	}
+}
+
+// generateKRL builds a KRL from an ssh-keygen revocation spec and parses it.
+func generateKRL(t *testing.T, spec string, keygenArgs ...string) *KRL {
+	t.Helper()
+	dir := t.TempDir()
+	specPath := filepath.Join(dir, "spec")
+	krlPath := filepath.Join(dir, "krl")
+	if err := os.WriteFile(specPath, []byte(spec), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	args := append([]string{"-q", "-k", "-f", krlPath}, keygenArgs...)
+	cmd := exec.Command("ssh-keygen", append(args, specPath)...)
+	if out, err := cmd.CombinedOutput(); err != nil {
+		t.Fatalf("ssh-keygen -k failed: %v\n%s", err, out)
+	}
+
+	krl, err := ParseKRL([]byte(readFile(t, krlPath)))
+	if err != nil {
+		t.Fatalf("ParseKRL failed: %v", err)
+	}
+	return krl
+}
+
+// signCert certifies the key at keyPath with the CA key at caPath.
+func signCert(t *testing.T, caPath, keyPath, keyID string, serial uint64) *ssh.Certificate {
+	t.Helper()
+	dir := t.TempDir()
+	pubPath := filepath.Join(dir, "key.pub")
+	if err := os.WriteFile(pubPath, []byte(readFile(t, keyPath+".pub")), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	cmd := exec.Command("ssh-keygen", "-q", "-s", caPath, "-I", keyID, "-z", strconv.FormatUint(serial, 10), "-n", "user", pubPath)
+	if out, err := cmd.CombinedOutput(); err != nil {
+		t.Fatalf("ssh-keygen -s failed: %v\n%s", err, out)
+	}
+
+	pub := readPublicKey(t, filepath.Join(dir, "key-cert"))
+	cert, ok := pub.(*ssh.Certificate)
+	if !ok {
+		t.Fatalf("got %T, want *ssh.Certificate", pub)
+	}
+	return cert
+}
+
+// fingerprint returns the SHA256 fingerprint of the key at keyPath.
+func fingerprint(t *testing.T, keyPath string) string {
+	t.Helper()
+	out, err := exec.Command("ssh-keygen", "-l", "-E", "sha256", "-f", keyPath+".pub").Output()
+	if err != nil {
+		t.Fatalf("ssh-keygen -l failed: %v", err)
+	}
+	fields := strings.Fields(string(out))
+	if len(fields) < 2 {
+		t.Fatalf("unexpected ssh-keygen -l output: %q", out)
+	}
+	return fields[1]
+}
+
+func readFile(t *testing.T, path string) string {
+	t.Helper()
+	b, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return string(b)
+}
@@ -0,0 +1,73 @@
+package sshminisig
+
+import (
+	"crypto/rand"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"io"
+	"slices"
+
+	"golang.org/x/crypto/ssh"
+)
+
+// Sign signs message in namespace with signer and returns the sshminisig,
+// without going through an armored signature.
+//
+// Messages are hashed with SHA-512, as ssh-keygen -Y sign does, unless the
+// sshminisig format only supports SHA-256 for the key type. RSA keys are
+// signed with rsa-sha2-512, so signer must be an ssh.AlgorithmSigner that
+// supports it; legacy ssh-rsa (SHA-1) signatures are rejected by OpenSSH.
+func Sign(signer ssh.Signer, namespace string, message io.Reader) (string, error) {
+	if signer == nil {
+		return "", errors.New("nil signer")
+	}
+	if namespace == "" {
+		return "", errors.New("empty namespace")
+	}
+
+	pub := signer.PublicKey()
+	if cert, ok := pub.(*ssh.Certificate); ok {
+		pub = cert.Key
+	}
+	sigAlg := SigAlg(pub.Type())
+	algSigner, isAlgSigner := signer.(ssh.AlgorithmSigner)
+	if sigAlg == SigLegacyRSA {
+		if !isAlgSigner {
+			return "", errors.New("RSA signer does not support rsa-sha2-512")
+		}
+		sigAlg = SigRSA512
+	}
+
+	hashAlg := HashSHA512
+	if _, ok := algsToPrefix[Algs{sigAlg, hashAlg}]; !ok {
+		hashAlg = HashSHA256
+	}
+	prefix, ok := algsToPrefix[Algs{sigAlg, hashAlg}]
+	if !ok {
+		return "", fmt.Errorf("unsupported key type: %q", pub.Type())
+	}
+
+	h, _ := newHash(hashAlg)
+	if _, err := io.Copy(h, message); err != nil {
+		return "", fmt.Errorf("reading message: %w", err)
+	}
+	data := signedData(namespace, hashAlg, h.Sum(nil))
+
+	var sig *ssh.Signature
+	var err error
+	if sigAlg == SigRSA512 {
+		sig, err = algSigner.SignWithAlgorithm(rand.Reader, data, string(sigAlg))
+	} else {
+		sig, err = signer.Sign(rand.Reader, data)
+	}
+	if err != nil {
+		return "", fmt.Errorf("signing: %w", err)
+	}
+	if sig.Format != string(sigAlg) {
+		return "", fmt.Errorf("signer produced %q signature, want %q", sig.Format, sigAlg)
+	}
+
+	sigData := slices.Concat(sig.Blob, sig.Rest)
+	return string(prefix) + base64.RawURLEncoding.EncodeToString(sigData), nil
+}
@@ -0,0 +1,108 @@
+package sshminisig
+
+import (
+	"crypto/ed25519"
+	"crypto/rand"
+	"errors"
+	"os"
+	"strings"
+	"testing"
+	"testing/iotest"
+
+	"golang.org/x/crypto/ssh"
+)
+
+func TestSign(t *testing.T) {
+	const message = "hello, minisig"
+
+	for _, kt := range verifyKeyTypes {
+		t.Run(kt.name, func(t *testing.T) {
+			keyPath := generateKey(t, kt.keygenArgs)
+			signer := readSigner(t, keyPath)
+
+			minisig, err := Sign(signer, "file", strings.NewReader(message))
+			if err != nil {
+				t.Fatalf("Sign failed: %v", err)
+			}
+			if err := Verify(signer.PublicKey(), "file", strings.NewReader(message), minisig); err != nil {
+				t.Errorf("Verify failed: %v", err)
+			}
+
+			// Ed25519 and RSA PKCS #1 v1.5 signatures are deterministic,
+			// so the result must match converting ssh-keygen's signature.
+			if kt.name != "ed25519" && kt.name != "rsa" {
+				return
+			}
+			want, err := Encode(signMessage(t, keyPath, "file", message))
+			if err != nil {
+				t.Fatalf("Encode failed: %v", err)
+			}
+			if minisig != want {
+				t.Errorf("got %q, want %q (from ssh-keygen)", minisig, want)
+			}
+		})
+	}
+}
+
+func TestSignGeneratedKey(t *testing.T) {
+	_, priv, err := ed25519.GenerateKey(rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	signer, err := ssh.NewSignerFromKey(priv)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	minisig, err := Sign(signer, "file", strings.NewReader("message"))
+	if err != nil {
+		t.Fatalf("Sign failed: %v", err)
+	}
+	if minisig[0] != PrefixEd25519 {
+		t.Errorf("prefix: got %c, want %c", minisig[0], PrefixEd25519)
+	}
+	if err := Verify(signer.PublicKey(), "file", strings.NewReader("message"), minisig); err != nil {
+		t.Errorf("Verify failed: %v", err)
+	}
+}
+
+// plainSigner hides any ssh.AlgorithmSigner implementation.
+type plainSigner struct {
+	ssh.Signer
+}
+
+func TestSignLegacyRSA(t *testing.T) {
+	signer := plainSigner{readSigner(t, generateKey(t, []string{"-t", "rsa", "-b", "2048"}))}
+
+	if _, err := Sign(signer, "file", strings.NewReader("message")); err == nil {
+		t.Error("expected error for RSA signer without rsa-sha2-512")
+	}
+}
+
+func TestSignErrors(t *testing.T) {
+	signer := readSigner(t, generateKey(t, []string{"-t", "ed25519"}))
+
+	if _, err := Sign(nil, "file", strings.NewReader("message")); err == nil {
+		t.Error("nil signer: expected error")
+	}
+	if _, err := Sign(signer, "", strings.NewReader("message")); err == nil {
+		t.Error("empty namespace: expected error")
+	}
+	if _, err := Sign(signer, "file", iotest.ErrReader(errors.New("boom"))); err == nil {
+		t.Error("read error: expected error")
+	}
+}
+
+// readSigner reads the private key at keyPath.
+func readSigner(t *testing.T, keyPath string) ssh.Signer {
+	t.Helper()
+	b, err := os.ReadFile(keyPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	signer, err := ssh.ParsePrivateKey(b)
+	if err != nil {
+		t.Fatalf("parsing private key: %v", err)
+	}
+	return signer
+}
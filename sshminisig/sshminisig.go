@@ -1,8 +1,19 @@
-// Package sshminisig converts Armored SSH Signatures to the compact sshminisig format.
+// Package sshminisig converts Armored SSH Signatures to and from the compact
+// sshminisig format, signs and verifies minisigs directly, and can reject keys
+// revoked by an OpenSSH KRL.
 //
 // The sshminisig format is:
 //   - one byte prefix indicating the combination of signature algorithm and hash algorithm
 //   - the signature, base64url-encoded, without padding
+//
+// Minisigs are expected to be checked on hot authentication paths, so they
+// are treated as attacker-controlled. Encode and Decode reject oversized
+// input before parsing it, and Decode does the same work for an unknown
+// prefix as for a malformed signature, so the error timing does not reveal
+// which check failed. The package never compares digests or signatures
+// itself; that check is left to ssh.PublicKey.Verify. Messages passed to
+// Verify and KRLs passed to ParseKRL are not size-bounded, so callers
+// should limit them.
 package sshminisig
 
 import (
@@ -88,6 +99,20 @@ const maxArmoredSize = 8 * 1024
 
 // Encode converts an armored SSH signature to sshminisig format.
 func Encode(armored []byte) (string, error) {
+	return EncodeOptions{}.Encode(armored)
+}
+
+// EncodeOptions configures Encode.
+type EncodeOptions struct {
+	// RequireNamespace, if non-empty, makes Encode fail unless the
+	// signature was made for exactly this namespace. Since a minisig
+	// does not carry its namespace, this is the last chance to catch
+	// a signature minted for a different purpose.
+	RequireNamespace string
+}
+
+// Encode converts an armored SSH signature to sshminisig format, subject to o.
+func (o EncodeOptions) Encode(armored []byte) (string, error) {
 	if len(armored) > maxArmoredSize {
 		return "", errors.New("armored signature too large")
 	}
@@ -96,10 +121,13 @@ func Encode(armored []byte) (string, error) {
 		return "", errors.New("invalid armored SSH signature")
 	}
 
-	sigAlg, hashAlg, sigData, err := parseSignatureBlob(block.Bytes)
+	sigAlg, hashAlg, namespace, sigData, err := parseSignatureBlob(block.Bytes)
 	if err != nil {
 		return "", err
 	}
+	if o.RequireNamespace != "" && namespace != o.RequireNamespace {
+		return "", fmt.Errorf("namespace mismatch: got %q, want %q", namespace, o.RequireNamespace)
+	}
 
 	prefix, ok := algsToPrefix[Algs{SigAlg(sigAlg), HashAlg(hashAlg)}]
 	if !ok {
@@ -109,28 +137,29 @@ func Encode(armored []byte) (string, error) {
 	return string(prefix) + base64.RawURLEncoding.EncodeToString(sigData), nil
 }
 
-// parseSignatureBlob parses the SSH signature blob and extracts the algorithm, hash, and signature data.
-func parseSignatureBlob(blob []byte) (sigAlgName, hashAlgName string, sigData []byte, err error) {
+// parseSignatureBlob parses the SSH signature blob and extracts the algorithm, hash, namespace, and signature data.
+func parseSignatureBlob(blob []byte) (sigAlgName, hashAlgName, namespace string, sigData []byte, err error) {
 	b := blob
 
 	// Verify magic preamble
 	if len(b) < 6 || string(b[:6]) != "SSHSIG" {
-		return "", "", nil, errors.New("invalid magic preamble")
+		return "", "", "", nil, errors.New("invalid magic preamble")
 	}
 	b = b[6:]
 
 	// Verify version
 	if len(b) < 4 || binary.BigEndian.Uint32(b[:4]) != 1 {
-		return "", "", nil, errors.New("invalid signature version")
+		return "", "", "", nil, errors.New("invalid signature version")
 	}
 	b = b[4:]
 
-	// Skip past public key, namespace, reserved
-	for range 3 {
-		_, b = readString(b)
-	}
+	// Skip past public key, read namespace, skip reserved
+	var ns []byte
+	_, b = readString(b)
+	ns, b = readString(b)
+	_, b = readString(b)
 	if b == nil {
-		return "", "", nil, errors.New("truncated signature blob")
+		return "", "", "", nil, errors.New("truncated signature blob")
 	}
 
 	// Read hash algorithm and signature blob
@@ -138,7 +167,7 @@ func parseSignatureBlob(blob []byte) (sigAlgName, hashAlgName string, sigData []
 	hashAlg, b = readString(b)
 	sigBlob, _ = readString(b)
 	if sigBlob == nil {
-		return "", "", nil, errors.New("invalid signature blob")
+		return "", "", "", nil, errors.New("invalid signature blob")
 	}
 
 	// Parse signature blob: algorithm + data + optional trailing data (SK flags/counter)
@@ -146,7 +175,7 @@ func parseSignatureBlob(blob []byte) (sigAlgName, hashAlgName string, sigData []
 	sigAlg, sigBlob = readString(sigBlob)
 	sigData, sigBlob = readString(sigBlob)
 	if sigData == nil {
-		return "", "", nil, errors.New("invalid signature blob")
+		return "", "", "", nil, errors.New("invalid signature blob")
 	}
 
 	// Append any remaining data (e.g., SK flags and counter)
@@ -154,7 +183,7 @@ func parseSignatureBlob(blob []byte) (sigAlgName, hashAlgName string, sigData []
 		sigData = append(sigData, sigBlob...)
 	}
 
-	return string(sigAlg), string(hashAlg), sigData, nil
+	return string(sigAlg), string(hashAlg), string(ns), sigData, nil
 }
 
 // readString reads an SSH-style string (uint32 length prefix + data).
@@ -175,13 +204,18 @@ func Decode(minisig string) (Algs, []byte, error) {
 	if len(minisig) < 2 {
 		return Algs{}, nil, errors.New("sshminisig too short")
 	}
+	// A minisig is always shorter than the armored signature it came from.
+	if len(minisig) > maxArmoredSize {
+		return Algs{}, nil, errors.New("sshminisig too large")
+	}
 
+	// Decode the signature before checking the prefix, so that an unknown
+	// prefix and invalid base64 cost the same.
 	algs := PrefixToAlgs[minisig[0]]
+	sigBytes, err := base64.RawURLEncoding.DecodeString(minisig[1:])
 	if algs.Sig == "" {
 		return Algs{}, nil, fmt.Errorf("unknown prefix: %c", minisig[0])
 	}
-
-	sigBytes, err := base64.RawURLEncoding.DecodeString(minisig[1:])
 	if err != nil {
 		return Algs{}, nil, fmt.Errorf("failed to decode signature: %w", err)
 	}
@@ -84,6 +84,28 @@ func TestEncode(t *testing.T) {
 	}
 }
 
+func TestEncodeRequireNamespace(t *testing.T) {
+	armored := generateSignature(t, []string{"-t", "ed25519"})
+	want, err := Encode(armored)
+	if err != nil {
+		t.Fatalf("Encode failed: %v", err)
+	}
+
+	got, err := EncodeOptions{RequireNamespace: "test"}.Encode(armored)
+	if err != nil {
+		t.Fatalf("Encode with matching namespace failed: %v", err)
+	}
+	if got != want {
+		t.Errorf("got %q, want %q", got, want)
+	}
+
+	for _, ns := range []string{"file", "tes", "test2"} {
+		if _, err := (EncodeOptions{RequireNamespace: ns}).Encode(armored); err == nil {
+			t.Errorf("RequireNamespace %q: expected error", ns)
+		}
+	}
+}
+
 func TestInvalidArmor(t *testing.T) {
 	_, err := Encode([]byte("not a valid armor"))
 	if err == nil {
@@ -127,9 +149,44 @@ func TestInvalidPrefix(t *testing.T) {
 	}
 }
 
+func TestDecodeErrors(t *testing.T) {
+	tests := []struct {
+		name    string
+		minisig string
+	}{
+		{"empty", ""},
+		{"prefix only", "e"},
+		{"unknown prefix", "xAAAA"},
+		{"reserved prefix", "zAAAA"},
+		{"invalid base64", "e!!!!"},
+		{"unknown prefix and invalid base64", "x!!!!"},
+		{"too large", "e" + strings.Repeat("A", maxArmoredSize)},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			algs, sigBytes, err := Decode(tc.minisig)
+			if err == nil {
+				t.Fatal("expected error")
+			}
+			if algs != (Algs{}) || sigBytes != nil {
+				t.Errorf("got %+v, %x on error, want zero values", algs, sigBytes)
+			}
+		})
+	}
+}
+
 // generateSignature creates a temp key and signs a test message.
 func generateSignature(t *testing.T, keygenArgs []string) []byte {
 	t.Helper()
+	keyPath := generateKey(t, keygenArgs)
+	return signMessage(t, keyPath, "test", "test message")
+}
+
+// generateKey creates a temp key and returns the path to its private half.
+// The public half is at keyPath + ".pub".
+func generateKey(t *testing.T, keygenArgs []string) string {
+	t.Helper()
 
 	if _, err := exec.LookPath("ssh-keygen"); err != nil {
 		t.Skip("ssh-keygen not available")
@@ -138,16 +195,20 @@ func generateSignature(t *testing.T, keygenArgs []string) []byte {
 	dir := t.TempDir()
 	keyPath := filepath.Join(dir, "testkey")
 
-	// Generate key
-	args := append([]string{"-f", keyPath, "-N", ""}, keygenArgs...)
+	args := append([]string{"-q", "-f", keyPath, "-N", ""}, keygenArgs...)
 	cmd := exec.Command("ssh-keygen", args...)
 	if out, err := cmd.CombinedOutput(); err != nil {
 		t.Fatalf("ssh-keygen generate failed: %v\n%s", err, out)
 	}
+	return keyPath
+}
+
+// signMessage signs message with the key at keyPath using ssh-keygen.
+func signMessage(t *testing.T, keyPath, namespace, message string) []byte {
+	t.Helper()
 
-	// Sign a test message
-	cmd = exec.Command("ssh-keygen", "-Y", "sign", "-f", keyPath, "-n", "test")
-	cmd.Stdin = strings.NewReader("test message")
+	cmd := exec.Command("ssh-keygen", "-Y", "sign", "-f", keyPath, "-n", namespace)
+	cmd.Stdin = strings.NewReader(message)
 	out, err := cmd.Output()
 	if err != nil {
 		if exitErr, ok := err.(*exec.ExitError); ok {
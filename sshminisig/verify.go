@@ -0,0 +1,193 @@
+package sshminisig
+
+import (
+	"crypto/sha256"
+	"crypto/sha512"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"hash"
+	"io"
+
+	"golang.org/x/crypto/ssh"
+)
+
+// skTrailerSize is the size of the flags byte and counter that
+// security key signatures carry after the signature itself.
+const skTrailerSize = 1 + 4
+
+// errLegacyRSA rejects ssh-rsa signatures, which use SHA-1.
+// OpenSSH's sshsig refuses them too.
+var errLegacyRSA = errors.New("legacy ssh-rsa (SHA-1) signatures are not supported")
+
+// Verify checks that minisig is a valid signature by pub, in namespace, over message.
+// It reads message to EOF, hashing it with the algorithm named by minisig's prefix.
+func Verify(pub ssh.PublicKey, namespace string, message io.Reader, minisig string) error {
+	if pub == nil {
+		return errors.New("nil public key")
+	}
+	if namespace == "" {
+		return errors.New("empty namespace")
+	}
+	algs, sigBytes, err := Decode(minisig)
+	if err != nil {
+		return err
+	}
+	if algs.Sig == SigLegacyRSA {
+		return errLegacyRSA
+	}
+	h, ok := newHash(algs.Hash)
+	if !ok {
+		return fmt.Errorf("unsupported hash algorithm: %q", algs.Hash)
+	}
+	if _, err := io.Copy(h, message); err != nil {
+		return fmt.Errorf("reading message: %w", err)
+	}
+	return verifyDigest(algs, sigBytes, pub, namespace, h.Sum(nil), algs.Hash)
+}
+
+// VerifyDigest checks that minisig is a valid signature by pub, in namespace,
+// over a message whose hashAlg digest is digest.
+//
+// It is for callers that hash the message themselves,
+// such as when streaming a large file or reusing a stored digest.
+func VerifyDigest(minisig string, pub ssh.PublicKey, namespace string, digest []byte, hashAlg HashAlg) error {
+	if pub == nil {
+		return errors.New("nil public key")
+	}
+	if namespace == "" {
+		return errors.New("empty namespace")
+	}
+	size, ok := hashSize(hashAlg)
+	if !ok {
+		return fmt.Errorf("unsupported hash algorithm: %q", hashAlg)
+	}
+	if len(digest) != size {
+		return fmt.Errorf("invalid %s digest length: %d", hashAlg, len(digest))
+	}
+
+	algs, sigBytes, err := Decode(minisig)
+	if err != nil {
+		return err
+	}
+	if algs.Sig == SigLegacyRSA {
+		return errLegacyRSA
+	}
+	return verifyDigest(algs, sigBytes, pub, namespace, digest, hashAlg)
+}
+
+// verifyDigest does the work of VerifyDigest on an already decoded minisig,
+// so that Verify decodes only once.
+func verifyDigest(algs Algs, sigBytes []byte, pub ssh.PublicKey, namespace string, digest []byte, hashAlg HashAlg) error {
+	if algs.Hash != hashAlg {
+		return fmt.Errorf("hash algorithm mismatch: signature uses %q, digest is %q", algs.Hash, hashAlg)
+	}
+
+	sig, err := sshSignature(algs.Sig, sigBytes)
+	if err != nil {
+		return err
+	}
+	if err := pub.Verify(signedData(namespace, hashAlg, digest), sig); err != nil {
+		return fmt.Errorf("signature verification failed: %w", err)
+	}
+	return nil
+}
+
+// A Verifier verifies minisigs subject to additional policy.
+// The zero Verifier behaves like the package-level functions.
+//
+// If pub is an *ssh.Certificate, the signature is checked against its
+// underlying key only. Verifier does not check that a trusted CA signed the
+// certificate, nor its validity window or principals, so callers must
+// validate certificates first, for example with ssh.CertChecker. A KRL
+// check on an unvalidated certificate proves nothing.
+type Verifier struct {
+	// KRL, if non-nil, makes verification fail with ErrRevoked
+	// for keys and certificates that it revokes.
+	KRL *KRL
+}
+
+// Verify is like the package-level Verify, but also applies v's policy.
+func (v *Verifier) Verify(pub ssh.PublicKey, namespace string, message io.Reader, minisig string) error {
+	if err := v.checkKey(pub); err != nil {
+		return err
+	}
+	return Verify(pub, namespace, message, minisig)
+}
+
+// VerifyDigest is like the package-level VerifyDigest, but also applies v's policy.
+func (v *Verifier) VerifyDigest(minisig string, pub ssh.PublicKey, namespace string, digest []byte, hashAlg HashAlg) error {
+	if err := v.checkKey(pub); err != nil {
+		return err
+	}
+	return VerifyDigest(minisig, pub, namespace, digest, hashAlg)
+}
+
+// checkKey applies v's key policy to pub.
+func (v *Verifier) checkKey(pub ssh.PublicKey) error {
+	if pub == nil {
+		return errors.New("nil public key")
+	}
+	if v.KRL != nil && v.KRL.IsRevoked(pub) {
+		return ErrRevoked
+	}
+	return nil
+}
+
+// newHash returns a new hash.Hash computing hashAlg.
+func newHash(hashAlg HashAlg) (hash.Hash, bool) {
+	switch hashAlg {
+	case HashSHA256:
+		return sha256.New(), true
+	case HashSHA512:
+		return sha512.New(), true
+	}
+	return nil, false
+}
+
+// hashSize reports the digest size of hashAlg.
+func hashSize(hashAlg HashAlg) (int, bool) {
+	switch hashAlg {
+	case HashSHA256:
+		return sha256.Size, true
+	case HashSHA512:
+		return sha512.Size, true
+	}
+	return 0, false
+}
+
+// sshSignature rebuilds the SSH signature from the algorithm and the
+// signature bytes of a minisig, splitting off any security key trailer.
+func sshSignature(sigAlg SigAlg, sigBytes []byte) (*ssh.Signature, error) {
+	sig := &ssh.Signature{Format: string(sigAlg), Blob: sigBytes}
+	if isSK(sigAlg) {
+		if len(sigBytes) < skTrailerSize {
+			return nil, errors.New("truncated security key signature")
+		}
+		n := len(sigBytes) - skTrailerSize
+		sig.Blob, sig.Rest = sigBytes[:n], sigBytes[n:]
+	}
+	return sig, nil
+}
+
+// isSK reports whether sigAlg is a security key (FIDO2) algorithm.
+func isSK(sigAlg SigAlg) bool {
+	return sigAlg == SigSKEd25519 || sigAlg == SigSKECDSA
+}
+
+// signedData builds the blob that an SSH signature actually covers,
+// as specified in OpenSSH's PROTOCOL.sshsig.
+func signedData(namespace string, hashAlg HashAlg, digest []byte) []byte {
+	b := []byte("SSHSIG")
+	b = appendString(b, []byte(namespace))
+	b = appendString(b, nil) // reserved
+	b = appendString(b, []byte(hashAlg))
+	b = appendString(b, digest)
+	return b
+}
+
+// appendString appends an SSH-style string (uint32 length prefix + data).
+func appendString(b, data []byte) []byte {
+	b = binary.BigEndian.AppendUint32(b, uint32(len(data)))
+	return append(b, data...)
+}
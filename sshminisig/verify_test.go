@@ -0,0 +1,208 @@
+package sshminisig
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/sha512"
+	"encoding/base64"
+	"errors"
+	"io"
+	"os"
+	"strings"
+	"testing"
+	"testing/iotest"
+
+	"golang.org/x/crypto/ssh"
+)
+
+// verifyKeyTypes are the key types exercised by the verification tests.
+var verifyKeyTypes = []struct {
+	name       string
+	keygenArgs []string
+}{
+	{"ed25519", []string{"-t", "ed25519"}},
+	{"ecdsa-p256", []string{"-t", "ecdsa", "-b", "256"}},
+	{"ecdsa-p384", []string{"-t", "ecdsa", "-b", "384"}},
+	{"ecdsa-p521", []string{"-t", "ecdsa", "-b", "521"}},
+	{"rsa", []string{"-t", "rsa", "-b", "2048"}},
+}
+
+func TestVerify(t *testing.T) {
+	const message = "hello, minisig"
+
+	for _, kt := range verifyKeyTypes {
+		t.Run(kt.name, func(t *testing.T) {
+			keyPath := generateKey(t, kt.keygenArgs)
+			pub := readPublicKey(t, keyPath)
+			minisig, err := Encode(signMessage(t, keyPath, "file", message))
+			if err != nil {
+				t.Fatalf("Encode failed: %v", err)
+			}
+
+			if err := Verify(pub, "file", strings.NewReader(message), minisig); err != nil {
+				t.Errorf("Verify failed: %v", err)
+			}
+			if err := Verify(pub, "file", strings.NewReader(message+"!"), minisig); err == nil {
+				t.Error("expected error for wrong message")
+			}
+			if err := Verify(pub, "git", strings.NewReader(message), minisig); err == nil {
+				t.Error("expected error for wrong namespace")
+			}
+		})
+	}
+}
+
+func TestVerifyErrors(t *testing.T) {
+	keyPath := generateKey(t, []string{"-t", "ed25519"})
+	pub := readPublicKey(t, keyPath)
+	minisig, err := Encode(signMessage(t, keyPath, "file", "message"))
+	if err != nil {
+		t.Fatalf("Encode failed: %v", err)
+	}
+
+	tests := []struct {
+		name      string
+		pub       ssh.PublicKey
+		namespace string
+		message   io.Reader
+		minisig   string
+	}{
+		{"nil key", nil, "file", strings.NewReader("message"), minisig},
+		{"empty namespace", pub, "", strings.NewReader("message"), minisig},
+		{"invalid minisig", pub, "file", strings.NewReader("message"), "x" + minisig[1:]},
+		{"read error", pub, "file", iotest.ErrReader(errors.New("boom")), minisig},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			if err := Verify(tc.pub, tc.namespace, tc.message, tc.minisig); err == nil {
+				t.Error("expected error")
+			}
+		})
+	}
+}
+
+func TestVerifyDigest(t *testing.T) {
+	const message = "hello, minisig"
+	digest := sha512.Sum512([]byte(message))
+
+	for _, kt := range verifyKeyTypes {
+		t.Run(kt.name, func(t *testing.T) {
+			keyPath := generateKey(t, kt.keygenArgs)
+			pub := readPublicKey(t, keyPath)
+			minisig, err := Encode(signMessage(t, keyPath, "file", message))
+			if err != nil {
+				t.Fatalf("Encode failed: %v", err)
+			}
+
+			if err := VerifyDigest(minisig, pub, "file", digest[:], HashSHA512); err != nil {
+				t.Errorf("VerifyDigest failed: %v", err)
+			}
+
+			other := sha512.Sum512([]byte("goodbye, minisig"))
+			if err := VerifyDigest(minisig, pub, "file", other[:], HashSHA512); err == nil {
+				t.Error("expected error for wrong digest")
+			}
+			if err := VerifyDigest(minisig, pub, "git", digest[:], HashSHA512); err == nil {
+				t.Error("expected error for wrong namespace")
+			}
+			otherPub := readPublicKey(t, generateKey(t, kt.keygenArgs))
+			if err := VerifyDigest(minisig, otherPub, "file", digest[:], HashSHA512); err == nil {
+				t.Error("expected error for wrong key")
+			}
+		})
+	}
+}
+
+func TestVerifyDigestErrors(t *testing.T) {
+	keyPath := generateKey(t, []string{"-t", "ed25519"})
+	pub := readPublicKey(t, keyPath)
+	minisig, err := Encode(signMessage(t, keyPath, "file", "message"))
+	if err != nil {
+		t.Fatalf("Encode failed: %v", err)
+	}
+	sha512Digest := sha512.Sum512([]byte("message"))
+	sha256Digest := sha256.Sum256([]byte("message"))
+
+	tests := []struct {
+		name      string
+		minisig   string
+		pub       ssh.PublicKey
+		namespace string
+		digest    []byte
+		hashAlg   HashAlg
+	}{
+		{"nil key", minisig, nil, "file", sha512Digest[:], HashSHA512},
+		{"empty namespace", minisig, pub, "", sha512Digest[:], HashSHA512},
+		{"unknown hash", minisig, pub, "file", sha512Digest[:], "md5"},
+		{"short digest", minisig, pub, "file", sha512Digest[:32], HashSHA512},
+		{"hash mismatch", minisig, pub, "file", sha256Digest[:], HashSHA256},
+		{"invalid minisig", "x" + minisig[1:], pub, "file", sha512Digest[:], HashSHA512},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			if err := VerifyDigest(tc.minisig, tc.pub, tc.namespace, tc.digest, tc.hashAlg); err == nil {
+				t.Error("expected error")
+			}
+		})
+	}
+}
+
+func TestVerifyLegacyRSA(t *testing.T) {
+	signer := readSigner(t, generateKey(t, []string{"-t", "rsa", "-b", "2048"}))
+	minisig := legacyRSAMinisig(t, signer, "file", "message")
+	digest := sha512.Sum512([]byte("message"))
+
+	if err := Verify(signer.PublicKey(), "file", strings.NewReader("message"), minisig); !errors.Is(err, errLegacyRSA) {
+		t.Errorf("Verify: got %v, want errLegacyRSA", err)
+	}
+	if err := VerifyDigest(minisig, signer.PublicKey(), "file", digest[:], HashSHA512); !errors.Is(err, errLegacyRSA) {
+		t.Errorf("VerifyDigest: got %v, want errLegacyRSA", err)
+	}
+}
+
+func TestSSHSignatureSK(t *testing.T) {
+	sigBytes := []byte{1, 2, 3, 4, 5, 6, 7, 8}
+	sig, err := sshSignature(SigSKEd25519, sigBytes)
+	if err != nil {
+		t.Fatalf("sshSignature failed: %v", err)
+	}
+	if string(sig.Blob) != string(sigBytes[:3]) || string(sig.Rest) != string(sigBytes[3:]) {
+		t.Errorf("got blob %x rest %x, want trailer split off", sig.Blob, sig.Rest)
+	}
+
+	if _, err := sshSignature(SigSKECDSA, sigBytes[:4]); err == nil {
+		t.Error("expected error for truncated security key signature")
+	}
+}
+
+// legacyRSAMinisig signs message with signer's legacy ssh-rsa algorithm
+// and returns the result as a minisig. The signature itself is valid,
+// so only the algorithm policy can reject it.
+func legacyRSAMinisig(t *testing.T, signer ssh.Signer, namespace, message string) string {
+	t.Helper()
+	digest := sha512.Sum512([]byte(message))
+	sig, err := signer.Sign(rand.Reader, signedData(namespace, HashSHA512, digest[:]))
+	if err != nil {
+		t.Fatalf("Sign failed: %v", err)
+	}
+	if sig.Format != string(SigLegacyRSA) {
+		t.Fatalf("got %q signature, want %q", sig.Format, SigLegacyRSA)
+	}
+	return string(PrefixLegacyRSA512) + base64.RawURLEncoding.EncodeToString(sig.Blob)
+}
+
+// readPublicKey reads the public half of the key at keyPath.
+func readPublicKey(t *testing.T, keyPath string) ssh.PublicKey {
+	t.Helper()
+	b, err := os.ReadFile(keyPath + ".pub")
+	if err != nil {
+		t.Fatal(err)
+	}
+	pub, _, _, _, err := ssh.ParseAuthorizedKey(b)
+	if err != nil {
+		t.Fatalf("parsing public key: %v", err)
+	}
+	return pub
+}
@@ -0,0 +1,18 @@
+// Globals that sshminisig.js relies on, declared for type-checking only.
+// wasm_exec.js defines Go; the Go program in main.go defines sshminisig.
+
+interface GoRuntime {
+	importObject: WebAssembly.Imports;
+	run(instance: WebAssembly.Instance): Promise<void>;
+}
+
+type RawResult<T> = T & { error?: string };
+
+interface RawSSHMinisig {
+	encode(armored: string): RawResult<{ minisig: string }>;
+	decode(minisig: string): RawResult<import("./sshminisig.js").Decoded>;
+	verify(publicKey: string, namespace: string, message: string | Uint8Array, minisig: string): RawResult<{}>;
+}
+
+declare var Go: (new () => GoRuntime) | undefined;
+declare var sshminisig: RawSSHMinisig | undefined;
@@ -0,0 +1,89 @@
+//go:build js && wasm
+
+// Command wasm exposes sshminisig to JavaScript when built for GOOS=js GOARCH=wasm.
+//
+// It registers a global sshminisig object and then blocks forever.
+// Use it through the wrapper in sshminisig.js rather than directly.
+package main
+
+import (
+	"bytes"
+	"syscall/js"
+
+	"github.com/boldsoftware/exe.dev/sshminisig"
+	"golang.org/x/crypto/ssh"
+)
+
+func main() {
+	js.Global().Set("sshminisig", js.ValueOf(map[string]any{
+		"encode": js.FuncOf(encode),
+		"decode": js.FuncOf(decode),
+		"verify": js.FuncOf(verify),
+	}))
+	select {}
+}
+
+// encode(armored: string) -> {minisig} | {error}
+func encode(this js.Value, args []js.Value) any {
+	if len(args) != 1 || args[0].Type() != js.TypeString {
+		return errorResult("encode: expected armored signature string")
+	}
+	minisig, err := sshminisig.Encode([]byte(args[0].String()))
+	if err != nil {
+		return errorResult(err.Error())
+	}
+	return map[string]any{"minisig": minisig}
+}
+
+// decode(minisig: string) -> {sig, hash, signature} | {error}
+func decode(this js.Value, args []js.Value) any {
+	if len(args) != 1 || args[0].Type() != js.TypeString {
+		return errorResult("decode: expected minisig string")
+	}
+	algs, sigBytes, err := sshminisig.Decode(args[0].String())
+	if err != nil {
+		return errorResult(err.Error())
+	}
+	return map[string]any{
+		"sig":       string(algs.Sig),
+		"hash":      string(algs.Hash),
+		"signature": bytesToJS(sigBytes),
+	}
+}
+
+// verify(publicKey: string, namespace: string, message: string | Uint8Array, minisig: string) -> {} | {error}
+//
+// publicKey is in authorized_keys format.
+func verify(this js.Value, args []js.Value) any {
+	if len(args) != 4 || args[0].Type() != js.TypeString || args[1].Type() != js.TypeString || args[3].Type() != js.TypeString {
+		return errorResult("verify: expected (publicKey, namespace, message, minisig)")
+	}
+	pub, _, _, _, err := ssh.ParseAuthorizedKey([]byte(args[0].String()))
+	if err != nil {
+		return errorResult("verify: invalid public key: " + err.Error())
+	}
+	var message []byte
+	switch m := args[2]; {
+	case m.Type() == js.TypeString:
+		message = []byte(m.String())
+	case m.InstanceOf(js.Global().Get("Uint8Array")):
+		message = make([]byte, m.Length())
+		js.CopyBytesToGo(message, m)
+	default:
+		return errorResult("verify: message must be a string or Uint8Array")
+	}
+	if err := sshminisig.Verify(pub, args[1].String(), bytes.NewReader(message), args[3].String()); err != nil {
+		return errorResult(err.Error())
+	}
+	return map[string]any{}
+}
+
+func errorResult(msg string) map[string]any {
+	return map[string]any{"error": msg}
+}
+
+func bytesToJS(b []byte) js.Value {
+	arr := js.Global().Get("Uint8Array").New(len(b))
+	js.CopyBytesToJS(arr, b)
+	return arr
+}
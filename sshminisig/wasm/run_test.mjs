@@ -0,0 +1,47 @@
+// Driver for TestWasm in ../wasm_test.go.
+//
+// Usage: node run_test.mjs <wasm_exec.js> <sshminisig.wasm> <public key> <namespace> <message> < armored.sig
+//
+// Encodes the armored signature on stdin, decodes and verifies the result,
+// and prints what happened as JSON.
+
+import { readFileSync } from "node:fs";
+import { pathToFileURL } from "node:url";
+import { load } from "./sshminisig.js";
+
+const [wasmExec, wasmPath, publicKey, namespace, message] = process.argv.slice(2);
+await import(pathToFileURL(wasmExec));
+const sshminisig = await load(readFileSync(wasmPath));
+
+const minisig = sshminisig.encode(readFileSync(0, "utf8"));
+const { sig, hash, signature } = sshminisig.decode(minisig);
+
+let decodeError = "";
+try {
+	sshminisig.decode("x!!!!");
+} catch (err) {
+	decodeError = err.message;
+}
+
+function verifyError(message) {
+	try {
+		sshminisig.verify(publicKey, namespace, message, minisig);
+		return "";
+	} catch (err) {
+		return err.message;
+	}
+}
+
+process.stdout.write(
+	JSON.stringify({
+		minisig,
+		sig,
+		hash,
+		signature: Buffer.from(signature).toString("base64url"),
+		decodeError,
+		verifyError: verifyError(message),
+		verifyBytesError: verifyError(new TextEncoder().encode(message)),
+		verifyWrongMessageError: verifyError(message + "!"),
+	}),
+);
+process.exit(0);
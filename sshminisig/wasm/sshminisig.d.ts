@@ -0,0 +1,29 @@
+// TypeScript declarations for sshminisig.js.
+
+/** Algorithm info and raw signature bytes, as returned by decode. */
+export interface Decoded {
+	/** SSH signature algorithm name, e.g. "ssh-ed25519". */
+	sig: string;
+	/** Hash algorithm name, "sha256" or "sha512". */
+	hash: string;
+	/** Raw signature bytes. */
+	signature: Uint8Array;
+}
+
+export interface SSHMinisig {
+	/** Converts an armored SSH signature to sshminisig format. Throws on invalid input. */
+	encode(armored: string): string;
+	/** Parses an sshminisig. Throws on invalid input. */
+	decode(minisig: string): Decoded;
+	/**
+	 * Checks that minisig is a valid signature by publicKey (in authorized_keys format),
+	 * in namespace, over message. Throws if it is not.
+	 */
+	verify(publicKey: string, namespace: string, message: string | Uint8Array, minisig: string): void;
+}
+
+/**
+ * Instantiates the sshminisig WebAssembly module.
+ * wasm_exec.js must be loaded first so that globalThis.Go is defined.
+ */
+export function load(source: Response | BufferSource | WebAssembly.Module): Promise<SSHMinisig>;
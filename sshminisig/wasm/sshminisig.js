@@ -0,0 +1,61 @@
+// JavaScript bindings for the sshminisig WebAssembly build.
+//
+// The Go runtime shim (wasm_exec.js, from $(go env GOROOT)/lib/wasm) must be
+// loaded first so that globalThis.Go is defined.
+
+// load instantiates the sshminisig WebAssembly module and returns its API.
+// source may be a fetch Response, the module bytes, or a compiled WebAssembly.Module.
+// The JSDoc types tie this implementation to sshminisig.d.ts; npm run typecheck checks them.
+/**
+ * @param {Response | BufferSource | WebAssembly.Module} source
+ * @returns {Promise<import("./sshminisig.js").SSHMinisig>}
+ */
+export async function load(source) {
+	if (typeof globalThis.Go !== "function") {
+		throw new Error("sshminisig: wasm_exec.js must be loaded before load()");
+	}
+	const go = new globalThis.Go();
+	/** @type {WebAssembly.Instance} */
+	let instance;
+	if (source instanceof WebAssembly.Module) {
+		instance = await WebAssembly.instantiate(source, go.importObject);
+	} else if (typeof Response !== "undefined" && source instanceof Response) {
+		({ instance } = await WebAssembly.instantiateStreaming(source, go.importObject));
+	} else {
+		// source is a BufferSource here, but the typeof Response guard
+		// keeps TypeScript from narrowing it.
+		const bytes = /** @type {BufferSource} */ (source);
+		({ instance } = await WebAssembly.instantiate(bytes, go.importObject));
+	}
+	// The Go program registers globalThis.sshminisig and then blocks,
+	// so run never resolves; don't await it.
+	go.run(instance);
+	const raw = globalThis.sshminisig;
+	if (!raw) {
+		throw new Error("sshminisig: wasm module did not register its API");
+	}
+	return {
+		encode(armored) {
+			return unwrap(raw.encode(armored)).minisig;
+		},
+		decode(minisig) {
+			const { sig, hash, signature } = unwrap(raw.decode(minisig));
+			return { sig, hash, signature };
+		},
+		verify(publicKey, namespace, message, minisig) {
+			unwrap(raw.verify(publicKey, namespace, message, minisig));
+		},
+	};
+}
+
+/**
+ * @template T
+ * @param {T & { error?: string }} result
+ * @returns {T}
+ */
+function unwrap(result) {
+	if (result.error !== undefined) {
+		throw new Error(`sshminisig: ${result.error}`);
+	}
+	return result;
+}
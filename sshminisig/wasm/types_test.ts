@@ -0,0 +1,29 @@
+// Compile-time checks of the public API in sshminisig.d.ts.
+// Checked by npm run typecheck; never executed.
+
+import { load, type Decoded, type SSHMinisig } from "./sshminisig.js";
+
+declare const wasm: ArrayBuffer;
+declare const response: Response;
+declare const compiled: WebAssembly.Module;
+
+export async function check(): Promise<void> {
+	const api: SSHMinisig = await load(wasm);
+	await load(response);
+	await load(compiled);
+
+	const minisig: string = api.encode("-----BEGIN SSH SIGNATURE-----");
+	const decoded: Decoded = api.decode(minisig);
+	const algs: [string, string] = [decoded.sig, decoded.hash];
+	const signature: Uint8Array = decoded.signature;
+
+	api.verify("ssh-ed25519 AAAA", "file", "message", minisig);
+	api.verify("ssh-ed25519 AAAA", "file", new Uint8Array(signature.length), minisig);
+	// @ts-expect-error verify throws on failure rather than returning a result.
+	const ok: boolean = api.verify("ssh-ed25519 AAAA", "file", "message", minisig);
+	// @ts-expect-error encode takes the armored signature as a string.
+	api.encode(signature);
+
+	void algs;
+	void ok;
+}
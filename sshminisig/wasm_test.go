@@ -0,0 +1,118 @@
+package sshminisig
+
+import (
+	"bytes"
+	"encoding/base64"
+	"encoding/json"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"testing"
+)
+
+// TestWasm checks that the js/wasm build, driven through sshminisig.js,
+// produces the same results as the native package.
+func TestWasm(t *testing.T) {
+	if testing.Short() {
+		t.Skip("skipping wasm build in short mode")
+	}
+	node, err := exec.LookPath("node")
+	if err != nil {
+		t.Skip("node not available")
+	}
+
+	keyPath := generateKey(t, []string{"-t", "ed25519"})
+	armored := signMessage(t, keyPath, "test", "test message")
+	pub, err := os.ReadFile(keyPath + ".pub")
+	if err != nil {
+		t.Fatal(err)
+	}
+	want, err := Encode(armored)
+	if err != nil {
+		t.Fatalf("Encode failed: %v", err)
+	}
+	wantAlgs, wantSig, err := Decode(want)
+	if err != nil {
+		t.Fatalf("Decode failed: %v", err)
+	}
+
+	wasmPath := filepath.Join(t.TempDir(), "sshminisig.wasm")
+	cmd := exec.Command("go", "build", "-o", wasmPath, "./wasm")
+	cmd.Env = append(os.Environ(), "GOOS=js", "GOARCH=wasm")
+	if out, err := cmd.CombinedOutput(); err != nil {
+		t.Fatalf("wasm build failed: %v\n%s", err, out)
+	}
+	goroot, err := exec.Command("go", "env", "GOROOT").Output()
+	if err != nil {
+		t.Fatalf("go env GOROOT: %v", err)
+	}
+	wasmExec := filepath.Join(string(bytes.TrimSpace(goroot)), "lib", "wasm", "wasm_exec.js")
+
+	cmd = exec.Command(node, "run_test.mjs", wasmExec, wasmPath, string(pub), "test", "test message")
+	cmd.Dir = "wasm"
+	cmd.Stdin = bytes.NewReader(armored)
+	out, err := cmd.Output()
+	if err != nil {
+		if exitErr, ok := err.(*exec.ExitError); ok {
+			t.Fatalf("node failed: %v\n%s", err, exitErr.Stderr)
+		}
+		t.Fatalf("node failed: %v", err)
+	}
+
+	var got struct {
+		Minisig     string `json:"minisig"`
+		Sig         string `json:"sig"`
+		Hash        string `json:"hash"`
+		Signature   string `json:"signature"`
+		DecodeError string `json:"decodeError"`
+
+		VerifyError             string `json:"verifyError"`
+		VerifyBytesError        string `json:"verifyBytesError"`
+		VerifyWrongMessageError string `json:"verifyWrongMessageError"`
+	}
+	if err := json.Unmarshal(out, &got); err != nil {
+		t.Fatalf("parsing node output %q: %v", out, err)
+	}
+
+	if got.Minisig != want {
+		t.Errorf("minisig: got %q, want %q", got.Minisig, want)
+	}
+	if got.Sig != string(wantAlgs.Sig) || got.Hash != string(wantAlgs.Hash) {
+		t.Errorf("algs: got %s/%s, want %s/%s", got.Sig, got.Hash, wantAlgs.Sig, wantAlgs.Hash)
+	}
+	if got.Signature != base64.RawURLEncoding.EncodeToString(wantSig) {
+		t.Errorf("signature bytes differ")
+	}
+	if got.DecodeError == "" {
+		t.Error("expected decode of invalid minisig to throw")
+	}
+	if got.VerifyError != "" {
+		t.Errorf("verify failed: %s", got.VerifyError)
+	}
+	if got.VerifyBytesError != "" {
+		t.Errorf("verify with Uint8Array message failed: %s", got.VerifyBytesError)
+	}
+	if got.VerifyWrongMessageError == "" {
+		t.Error("expected verify of wrong message to throw")
+	}
+}
+
+// TestTypeScript type-checks sshminisig.js against sshminisig.d.ts,
+// and the declarations against types_test.ts.
+func TestTypeScript(t *testing.T) {
+	tsc := filepath.Join("wasm", "node_modules", ".bin", "tsc")
+	if _, err := os.Stat(tsc); err != nil {
+		if tsc, err = exec.LookPath("tsc"); err != nil {
+			// CI installs tsc, so a missing one there is a setup bug, not a skip.
+			if os.Getenv("CI") != "" {
+				t.Fatal("tsc not available; run npm install in wasm")
+			}
+			t.Skip("tsc not available; run npm install in wasm")
+		}
+	}
+
+	cmd := exec.Command(tsc, "-p", "wasm")
+	if out, err := cmd.CombinedOutput(); err != nil {
+		t.Fatalf("tsc failed: %v\n%s", err, out)
+	}
+}